@@ -0,0 +1,42 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// TestCalculateDirSizeWithProgressAggregatesErrors covers the error/unknown-
+// size rollup chunk0-2 added to calculateDirSizeWithProgress: an unreadable
+// subdirectory must surface as EntriesHaveErrors/UnknownSizeFiles on every
+// ancestor, not just the node that failed.
+func TestCalculateDirSizeWithProgressAggregatesErrors(t *testing.T) {
+	dir := t.TempDir()
+	blocked := filepath.Join(dir, "blocked")
+	if err := os.Mkdir(blocked, 0755); err != nil {
+		t.Fatalf("setup: %v", err)
+	}
+	if err := os.Chmod(blocked, 0); err != nil {
+		t.Fatalf("setup: %v", err)
+	}
+	t.Cleanup(func() { os.Chmod(blocked, 0755) })
+
+	if os.Getuid() == 0 {
+		t.Skip("running as root: permission denial is not enforced")
+	}
+
+	root, err := calculateDirSizeWithProgress(dir, nil, newScanSemaphore(2), &scanCounters{}, scanConfig{})
+	if err != nil {
+		t.Fatalf("calculateDirSizeWithProgress: %v", err)
+	}
+
+	if !root.EntriesHaveErrors {
+		t.Fatalf("expected root.EntriesHaveErrors, got false: %+v", root)
+	}
+	if root.UnknownSizeFiles == 0 {
+		t.Fatalf("expected root.UnknownSizeFiles > 0, got 0: %+v", root)
+	}
+	if len(collectErrors(root)) == 0 {
+		t.Fatalf("expected at least one recorded FileError")
+	}
+}
@@ -0,0 +1,111 @@
+package main
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// buildSampleTree returns a small tree with a mix of files, a nested
+// directory and a recorded scan error, for exercising export/import.
+func buildSampleTree() *DirInfo {
+	root := &DirInfo{Path: "/root", IsDir: true}
+
+	file := &DirInfo{Path: "/root/file.txt", Parent: root, Size: 100, TotalFiles: 1}
+
+	sub := &DirInfo{Path: "/root/sub", Parent: root, IsDir: true}
+	subFile := &DirInfo{Path: "/root/sub/nested.txt", Parent: sub, Size: 50, TotalFiles: 1}
+	sub.Children = []*DirInfo{subFile}
+	sub.Size = subFile.Size
+	sub.TotalFiles = subFile.TotalFiles
+
+	bad := &DirInfo{
+		Path:             "/root/bad",
+		Parent:           root,
+		IsDir:            true,
+		Error:            errors.New("permission denied"),
+		TotalFiles:       1,
+		UnknownSizeFiles: 1,
+	}
+
+	root.Children = []*DirInfo{file, sub, bad}
+	root.Size = file.Size + sub.Size
+	root.TotalFiles = file.TotalFiles + sub.TotalFiles + bad.TotalFiles
+	root.UnknownSizeFiles = bad.UnknownSizeFiles
+	root.EntriesHaveErrors = true
+
+	return root
+}
+
+func TestExportImportJSONRoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "tree.json")
+	original := buildSampleTree()
+
+	if err := exportTree(original, path, "json"); err != nil {
+		t.Fatalf("exportTree: %v", err)
+	}
+
+	got, err := importTree(path)
+	if err != nil {
+		t.Fatalf("importTree: %v", err)
+	}
+
+	if got.Path != original.Path || got.Size != original.Size {
+		t.Fatalf("root mismatch: got %+v, want %+v", got, original)
+	}
+	if len(got.Children) != len(original.Children) {
+		t.Fatalf("child count mismatch: got %d, want %d", len(got.Children), len(original.Children))
+	}
+
+	// recomputeAggregates must reconstruct the derived fields from the
+	// minimal on-disk schema, not just copy Path/Size/IsDir/Error across.
+	if !got.EntriesHaveErrors {
+		t.Errorf("EntriesHaveErrors did not round-trip: got %+v", got)
+	}
+	if got.UnknownSizeFiles != original.UnknownSizeFiles {
+		t.Errorf("UnknownSizeFiles = %d, want %d", got.UnknownSizeFiles, original.UnknownSizeFiles)
+	}
+	if got.TotalFiles != original.TotalFiles {
+		t.Errorf("TotalFiles = %d, want %d", got.TotalFiles, original.TotalFiles)
+	}
+
+	// Parent pointers must be rebuilt, not left nil.
+	for _, child := range got.Children {
+		if child.Parent != got {
+			t.Errorf("child %s has Parent %v, want %v", child.Path, child.Parent, got)
+		}
+	}
+}
+
+func TestExportCSVIsFlatPathSizeIsDir(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "tree.csv")
+	original := buildSampleTree()
+
+	if err := exportTree(original, path, "csv"); err != nil {
+		t.Fatalf("exportTree: %v", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("reading export: %v", err)
+	}
+
+	lines := strings.Split(strings.TrimRight(string(data), "\n"), "\n")
+	// header + one row per node in the tree (root, file, sub, nested, bad).
+	if want := 1 + countNodes(original); len(lines) != want {
+		t.Fatalf("got %d lines, want %d: %q", len(lines), want, data)
+	}
+	if lines[0] != "path,size_bytes,is_dir" {
+		t.Fatalf("unexpected header: %q", lines[0])
+	}
+}
+
+func countNodes(d *DirInfo) int {
+	n := 1
+	for _, child := range d.Children {
+		n += countNodes(child)
+	}
+	return n
+}
@@ -0,0 +1,86 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+)
+
+// TestCalculateDirSizeWithProgressIgnoreDir covers the --ignore-dir glob
+// filtering chunk0-4 added: a matching directory name must not be descended
+// into and must show up in its parent's Skipped list.
+func TestCalculateDirSizeWithProgressIgnoreDir(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(dir, "node_modules", "pkg"), 0755); err != nil {
+		t.Fatalf("setup: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "node_modules", "pkg", "big.bin"), make([]byte, 1024), 0644); err != nil {
+		t.Fatalf("setup: %v", err)
+	}
+	if err := os.MkdirAll(filepath.Join(dir, "src"), 0755); err != nil {
+		t.Fatalf("setup: %v", err)
+	}
+
+	cfg := scanConfig{ignoreDirs: []string{"node_modules"}}
+	root, err := calculateDirSizeWithProgress(dir, nil, newScanSemaphore(2), &scanCounters{}, cfg)
+	if err != nil {
+		t.Fatalf("calculateDirSizeWithProgress: %v", err)
+	}
+
+	if len(root.Skipped) != 1 || root.Skipped[0] != "node_modules" {
+		t.Fatalf("Skipped = %v, want [node_modules]", root.Skipped)
+	}
+	for _, child := range root.Children {
+		if filepath.Base(child.Path) == "node_modules" {
+			t.Fatalf("node_modules was descended into despite matching --ignore-dir: %+v", child)
+		}
+	}
+}
+
+// TestCalculateDirSizeWithProgressOneFileSystemFollowsSymlinks covers the
+// --one-file-system / -x boundary check chunk0-4 added. entry.IsDir() is
+// false for a symlink, so the skip check must resolve the link's target
+// before comparing devices, or a symlinked directory silently crosses the
+// filesystem boundary that -x is supposed to stop at.
+func TestCalculateDirSizeWithProgressOneFileSystemFollowsSymlinks(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("symlink creation requires elevated privileges on windows")
+	}
+
+	root := t.TempDir()
+	linked := t.TempDir() // a distinct temp dir; not guaranteed cross-device, see rootDevice override below
+	if err := os.WriteFile(filepath.Join(linked, "payload.bin"), make([]byte, 4096), 0644); err != nil {
+		t.Fatalf("setup: %v", err)
+	}
+	if err := os.Symlink(linked, filepath.Join(root, "mnt")); err != nil {
+		t.Fatalf("setup symlink: %v", err)
+	}
+
+	rootInfo, err := os.Stat(root)
+	if err != nil {
+		t.Fatalf("stat root: %v", err)
+	}
+	dev, ok := deviceID(rootInfo)
+	if !ok {
+		t.Skip("deviceID unsupported on this platform")
+	}
+
+	// Force the boundary check to treat the symlink target as foreign,
+	// regardless of whether the two temp dirs actually share a device.
+	cfg := scanConfig{oneFileSystem: true, rootDevice: dev + 1}
+
+	got, err := calculateDirSizeWithProgress(root, nil, newScanSemaphore(2), &scanCounters{}, cfg)
+	if err != nil {
+		t.Fatalf("calculateDirSizeWithProgress: %v", err)
+	}
+
+	if len(got.Skipped) != 1 || got.Skipped[0] != "mnt" {
+		t.Fatalf("Skipped = %v, want [mnt]; symlinked directory crossed the --one-file-system boundary unnoticed", got.Skipped)
+	}
+	for _, child := range got.Children {
+		if filepath.Base(child.Path) == "mnt" {
+			t.Fatalf("mnt was descended into despite crossing the --one-file-system boundary: %+v", child)
+		}
+	}
+}
@@ -0,0 +1,11 @@
+//go:build windows
+
+package main
+
+import "os"
+
+// deviceID is a no-op on Windows: there's no portable equivalent of
+// syscall.Stat_t.Dev, so --one-file-system can't be enforced here.
+func deviceID(info os.FileInfo) (uint64, bool) {
+	return 0, false
+}
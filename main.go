@@ -1,17 +1,27 @@
 package main
 
 import (
+	"flag"
 	"fmt"
 	"os"
 	"path/filepath"
+	"runtime"
 	"sort"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
 )
 
+// FileError records a path that failed to stat, read or remove during a scan.
+type FileError struct {
+	Path string
+	Err  error
+}
+
 // DirInfo holds information about a directory and its size
 type DirInfo struct {
 	Path     string
@@ -20,29 +30,96 @@ type DirInfo struct {
 	Parent   *DirInfo
 	IsDir    bool
 	Error    error
+
+	Errors            []FileError // errors for entries directly inside this directory
+	EntriesHaveErrors bool        // true if this dir or anything beneath it failed to scan
+	TotalFiles        int64       // count of files in this subtree
+	UnknownSizeFiles  int64       // count of files in this subtree whose size could not be determined
+	Skipped           []string    // names of direct children not descended into (--ignore-dir or --one-file-system)
+
+	mu sync.Mutex // guards Children/Errors/Skipped while the concurrent scanner is still appending to them
 }
 
 // Model represents the application state
 type model struct {
-	rootDir        *DirInfo
-	currentDir     *DirInfo
-	cursor         int
-	scanning       bool
-	scanError      error
-	width          int
-	height         int
-	startTime      time.Time
-	sortBy         string // "size" or "name"
-	statusMsg      string
-	statusExpiry   time.Time
-	deleting       bool
-	deleteSpinner  int
-	deleteTarget   *DirInfo
-	deleteFilename string
-	scanProgress   *DirInfo // Current state during scanning
-	filesScanned   int
-	dirsScanned    int
-	totalSize      int64
+	rootDir         *DirInfo
+	currentDir      *DirInfo
+	cursor          int
+	scanning        bool
+	scanError       error
+	width           int
+	height          int
+	startTime       time.Time
+	sortBy          string // "size" or "name"
+	statusMsg       string
+	statusExpiry    time.Time
+	deleting        bool
+	deleteSpinner   int
+	deleteTarget    *DirInfo
+	deleteFilename  string
+	scanProgressTop []*DirInfo // Largest root-level children found so far during scanning
+	filesScanned    int
+	dirsScanned     int
+	totalSize       int64
+	path            string
+	jobs            int
+	showErrors      bool
+	errorList       []FileError
+	errorScroll     int
+	rescanning      bool
+	rescanTarget    string
+	cfg             scanConfig
+	treeView        bool
+	expanded        map[*DirInfo]bool
+	exportPath      string
+	exportFormat    string
+}
+
+// scanConfig holds scan-wide options resolved once in main and reused,
+// unmodified, by every recursive call of a given scan or rescan.
+type scanConfig struct {
+	oneFileSystem bool
+	rootDevice    uint64
+	ignoreDirs    []string
+}
+
+// shouldSkipDir reports whether a directory entry should not be descended
+// into, because it matches an --ignore-dir pattern or, under
+// --one-file-system, resolves (following symlinks) to a different device
+// than the scan root. The device check uses os.Stat rather than os.Lstat so
+// a symlink to another filesystem is caught by its target's device, not the
+// link inode's own device on the source filesystem.
+func (cfg scanConfig) shouldSkipDir(name, path string) bool {
+	for _, pattern := range cfg.ignoreDirs {
+		if matched, _ := filepath.Match(pattern, name); matched {
+			return true
+		}
+	}
+
+	if cfg.oneFileSystem {
+		if info, err := os.Stat(path); err == nil {
+			if dev, ok := deviceID(info); ok && dev != cfg.rootDevice {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// isOrResolvesToDir reports whether entry is a directory itself, or a
+// symlink whose target is a directory. entry.IsDir() alone reflects only
+// the entry's own (unresolved) type, so a symlinked directory would
+// otherwise bypass shouldSkipDir entirely.
+func isOrResolvesToDir(entry os.DirEntry, path string) bool {
+	if entry.IsDir() {
+		return true
+	}
+	if entry.Type()&os.ModeSymlink == 0 {
+		return false
+	}
+	target, err := os.Stat(path)
+	return err == nil && target.IsDir()
 }
 
 type scanCompleteMsg struct {
@@ -50,11 +127,12 @@ type scanCompleteMsg struct {
 	err  error
 }
 
-type scanProgressMsg struct {
-	root         *DirInfo
-	filesScanned int
-	dirsScanned  int
-	totalSize    int64
+// rescanCompleteMsg carries the result of re-walking a single subtree,
+// triggered by the 'r' keybinding.
+type rescanCompleteMsg struct {
+	target *DirInfo // the existing node to splice the fresh results into
+	fresh  *DirInfo
+	err    error
 }
 
 type scanTickMsg struct{}
@@ -97,21 +175,63 @@ var (
 			Foreground(lipgloss.Color("#FF0000")).
 			Bold(true)
 
+	warnStyle = lipgloss.NewStyle().
+			Foreground(lipgloss.Color("#FFD700")).
+			Bold(true)
+
 	helpStyle = lipgloss.NewStyle().
 			Foreground(lipgloss.Color("#666666")).
 			Italic(true)
 )
 
-func initialModel(path string) model {
+func initialModel(path string, jobs int, cfg scanConfig) model {
 	return model{
-		scanning:   true,
-		startTime:  time.Now(),
-		sortBy:     "size",
+		scanning:  true,
+		startTime: time.Now(),
+		sortBy:    "size",
+		path:      path,
+		jobs:      jobs,
+		cfg:       cfg,
+	}
+}
+
+// importedModel builds a model from a tree loaded with --import, skipping
+// the scanning phase entirely and going straight to the interactive UI.
+func importedModel(root *DirInfo, jobs int, exportPath, exportFormat string) model {
+	return model{
+		scanning:     false,
+		rootDir:      root,
+		currentDir:   root,
+		startTime:    time.Now(),
+		sortBy:       "size",
+		jobs:         jobs,
+		exportPath:   exportPath,
+		exportFormat: exportFormat,
 	}
 }
 
 func (m model) Init() tea.Cmd {
-	return tea.Batch(scanDirectory, scanTick)
+	if !m.scanning {
+		// A model built by importedModel already has its tree; nothing to scan.
+		return nil
+	}
+	return tea.Batch(m.scanCmd(), scanTick)
+}
+
+// scanCmd kicks off the scan of m.path bounded by m.jobs concurrent workers.
+func (m model) scanCmd() tea.Cmd {
+	path, jobs, cfg := m.path, m.jobs, m.cfg
+	return func() tea.Msg {
+		return scanDirectory(path, jobs, cfg)
+	}
+}
+
+// rescanCmd re-walks target's subtree in place, used by the 'r' keybinding.
+func (m model) rescanCmd(target *DirInfo) tea.Cmd {
+	jobs, cfg := m.jobs, m.cfg
+	return func() tea.Msg {
+		return rescanDirectory(target, jobs, cfg)
+	}
 }
 
 // scanTick sends periodic ticks during scanning
@@ -120,45 +240,84 @@ func scanTick() tea.Msg {
 	return scanTickMsg{}
 }
 
-// Global progress channel for scanning
-var progressChan = make(chan scanProgressMsg, 100)
+// Global root reference for progress updates. The scan goroutine publishes
+// through Store/CompareAndSwap and the UI goroutine's scanTickMsg handler
+// reads through Load, so the hand-off is race-free without a mutex.
+var currentRoot atomic.Pointer[DirInfo]
+
+// scanCounters holds the atomically-updated progress counters for an
+// in-flight scan. scanTickMsg reads them directly instead of draining a
+// lossy progress channel, so no update is ever missed or reordered.
+type scanCounters struct {
+	filesScanned atomic.Int64
+	dirsScanned  atomic.Int64
+	totalSize    atomic.Int64
+}
 
-// Global root reference for progress updates
-var currentRoot *DirInfo
+// currentCounters points at the counters for the scan in progress, if any.
+var currentCounters atomic.Pointer[scanCounters]
 
-// scanDirectory scans the directory tree
-func scanDirectory() tea.Msg {
-	path := "."
-	if len(os.Args) > 1 {
-		path = os.Args[1]
+// scanSemaphore bounds the number of directory subtrees walked concurrently,
+// the same concurrencyLimitChannel pattern gdu uses to cap fan-out.
+type scanSemaphore chan struct{}
+
+func newScanSemaphore(n int) scanSemaphore {
+	if n < 1 {
+		n = 1
 	}
+	return make(scanSemaphore, n)
+}
 
-	filesScanned := 0
-	dirsScanned := 0
-	currentRoot = nil
-	root, err := calculateDirSizeWithProgress(path, nil, &filesScanned, &dirsScanned)
-	if err != nil && root == nil {
-		return scanCompleteMsg{nil, err}
+func (s scanSemaphore) release() { <-s }
+
+// tryAcquire claims a slot without blocking, reporting whether one was free.
+// Recursive calls must never block waiting for a slot here: a parent
+// goroutine that already holds one would deadlock against its own children
+// trying to acquire from the same semaphore while it sits in wg.Wait(). When
+// no slot is free we fall back to running the child inline on the calling
+// goroutine instead of waiting for one.
+func (s scanSemaphore) tryAcquire() bool {
+	select {
+	case s <- struct{}{}:
+		return true
+	default:
+		return false
 	}
+}
 
-	// Send final progress update
-	if currentRoot != nil {
-		select {
-		case progressChan <- scanProgressMsg{
-			root:         currentRoot,
-			filesScanned: filesScanned,
-			dirsScanned:  dirsScanned,
-			totalSize:    currentRoot.Size,
-		}:
-		default:
-		}
+// scanDirectory scans the directory tree rooted at path using up to jobs
+// concurrent workers.
+func scanDirectory(path string, jobs int, cfg scanConfig) tea.Msg {
+	currentRoot.Store(nil)
+	counters := &scanCounters{}
+	currentCounters.Store(counters)
+
+	root, err := calculateDirSizeWithProgress(path, nil, newScanSemaphore(jobs), counters, cfg)
+	if err != nil && root == nil {
+		return scanCompleteMsg{nil, err}
 	}
 
 	return scanCompleteMsg{root, nil}
 }
 
-// calculateDirSizeWithProgress recursively calculates directory sizes with progress tracking
-func calculateDirSizeWithProgress(path string, parent *DirInfo, filesScanned, dirsScanned *int) (*DirInfo, error) {
+// rescanDirectory re-walks target's subtree, reporting progress through the
+// same atomic counters scanTick reads during a full scan.
+func rescanDirectory(target *DirInfo, jobs int, cfg scanConfig) tea.Msg {
+	currentRoot.Store(nil)
+	counters := &scanCounters{}
+	currentCounters.Store(counters)
+
+	fresh, err := calculateDirSizeWithProgress(target.Path, target.Parent, newScanSemaphore(jobs), counters, cfg)
+	return rescanCompleteMsg{target: target, fresh: fresh, err: err}
+}
+
+// calculateDirSizeWithProgress recursively calculates directory sizes,
+// walking each directory's children through sem so that at most sem's
+// capacity worth of subtrees run on goroutines of their own at once; a child
+// that finds no free slot is walked inline by its caller. Children of the same
+// DirInfo are appended under dirInfo.mu since siblings are scanned
+// concurrently.
+func calculateDirSizeWithProgress(path string, parent *DirInfo, sem scanSemaphore, counters *scanCounters, cfg scanConfig) (*DirInfo, error) {
 	info, err := os.Stat(path)
 	if err != nil {
 		return nil, err
@@ -171,52 +330,103 @@ func calculateDirSizeWithProgress(path string, parent *DirInfo, filesScanned, di
 	}
 
 	// Set root reference on first call
-	if parent == nil && currentRoot == nil {
-		currentRoot = dirInfo
+	if parent == nil {
+		currentRoot.CompareAndSwap(nil, dirInfo)
 	}
 
 	if !info.IsDir() {
 		dirInfo.Size = info.Size()
-		*filesScanned++
+		dirInfo.TotalFiles = 1
+		counters.filesScanned.Add(1)
+		counters.totalSize.Add(dirInfo.Size)
 		return dirInfo, nil
 	}
 
-	*dirsScanned++
+	counters.dirsScanned.Add(1)
 	entries, err := os.ReadDir(path)
 	if err != nil {
 		dirInfo.Error = err
+		dirInfo.Errors = append(dirInfo.Errors, FileError{Path: path, Err: err})
+		dirInfo.EntriesHaveErrors = true
+		// The directory's contents are unknown, so count it as a single
+		// entry of unknown size rather than leaving totals untouched.
+		dirInfo.TotalFiles = 1
+		dirInfo.UnknownSizeFiles = 1
 		return dirInfo, nil
 	}
 
-	var totalSize int64
+	var wg sync.WaitGroup
 	for _, entry := range entries {
+		entry := entry
 		childPath := filepath.Join(path, entry.Name())
 
-		child, err := calculateDirSizeWithProgress(childPath, dirInfo, filesScanned, dirsScanned)
-		if err != nil {
-			// Skip entries we can't access
+		if isOrResolvesToDir(entry, childPath) && cfg.shouldSkipDir(entry.Name(), childPath) {
+			dirInfo.mu.Lock()
+			dirInfo.Skipped = append(dirInfo.Skipped, entry.Name())
+			dirInfo.mu.Unlock()
 			continue
 		}
 
-		dirInfo.Children = append(dirInfo.Children, child)
-		totalSize += child.Size
+		scanChild := func() {
+			child, err := calculateDirSizeWithProgress(childPath, dirInfo, sem, counters, cfg)
+			if err != nil {
+				// The entry couldn't be stat'd at all; keep it visible with
+				// an unknown size instead of silently dropping it.
+				child = &DirInfo{
+					Path:             childPath,
+					Parent:           dirInfo,
+					IsDir:            entry.IsDir(),
+					Error:            err,
+					TotalFiles:       1,
+					UnknownSizeFiles: 1,
+				}
+				counters.filesScanned.Add(1)
 
-		// After completing each child of root, send progress update
-		if dirInfo == currentRoot && currentRoot != nil {
-			dirInfo.Size = totalSize // Update root size incrementally
-			select {
-			case progressChan <- scanProgressMsg{
-				root:         currentRoot,
-				filesScanned: *filesScanned,
-				dirsScanned:  *dirsScanned,
-				totalSize:    totalSize,
-			}:
-			default:
+				dirInfo.mu.Lock()
+				dirInfo.Errors = append(dirInfo.Errors, FileError{Path: childPath, Err: err})
+				dirInfo.mu.Unlock()
 			}
+
+			dirInfo.mu.Lock()
+			dirInfo.Children = append(dirInfo.Children, child)
+			dirInfo.mu.Unlock()
+		}
+
+		// Only dispatch a fresh goroutine when a slot is actually free; a
+		// goroutine that blocked here while already holding a slot of its
+		// own would deadlock against its descendants competing for the same
+		// semaphore, so a full pool falls back to scanning this child
+		// inline instead of waiting.
+		if sem.tryAcquire() {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				defer sem.release()
+				scanChild()
+			}()
+		} else {
+			scanChild()
 		}
 	}
+	wg.Wait()
 
+	var totalSize, totalFiles, unknownSizeFiles int64
+	hasErrors := len(dirInfo.Errors) > 0
+	for _, child := range dirInfo.Children {
+		totalSize += child.Size
+		totalFiles += child.TotalFiles
+		unknownSizeFiles += child.UnknownSizeFiles
+		if child.Error != nil || child.EntriesHaveErrors {
+			hasErrors = true
+		}
+	}
 	dirInfo.Size = totalSize
+	dirInfo.TotalFiles = totalFiles
+	dirInfo.UnknownSizeFiles = unknownSizeFiles
+	dirInfo.EntriesHaveErrors = hasErrors
+	// snapshotTopChildren may be copying Children from the UI goroutine.
+	dirInfo.mu.Lock()
+	defer dirInfo.mu.Unlock()
 
 	// Sort children by size (descending)
 	sort.Slice(dirInfo.Children, func(i, j int) bool {
@@ -286,24 +496,62 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		m.rootDir = msg.root
 		m.currentDir = msg.root
 		m.scanError = msg.err
-		m.scanProgress = nil
+		m.scanProgressTop = nil
+
+		if msg.err == nil && m.exportPath != "" {
+			if err := exportTree(m.rootDir, m.exportPath, m.exportFormat); err != nil {
+				m.statusMsg = fmt.Sprintf("Export failed: %v", err)
+			} else {
+				m.statusMsg = fmt.Sprintf("Exported to %s", m.exportPath)
+			}
+			m.statusExpiry = time.Now().Add(3 * time.Second)
+		}
 		return m, nil
 
 	case scanTickMsg:
-		// Check for progress updates from the channel
-		if m.scanning {
-			select {
-			case progress := <-progressChan:
-				m.scanProgress = progress.root
-				m.filesScanned = progress.filesScanned
-				m.dirsScanned = progress.dirsScanned
-				m.totalSize = progress.totalSize
-			default:
+		// Read the atomic progress counters for the in-flight scan or rescan
+		if m.scanning || m.rescanning {
+			if counters := currentCounters.Load(); counters != nil {
+				m.filesScanned = int(counters.filesScanned.Load())
+				m.dirsScanned = int(counters.dirsScanned.Load())
+				m.totalSize = counters.totalSize.Load()
 			}
+			m.scanProgressTop = snapshotTopChildren(currentRoot.Load())
 			return m, scanTick
 		}
 		return m, nil
 
+	case rescanCompleteMsg:
+		m.rescanning = false
+		m.scanProgressTop = nil
+
+		if msg.err != nil {
+			m.statusMsg = fmt.Sprintf("Rescan failed: %v", msg.err)
+			m.statusExpiry = time.Now().Add(5 * time.Second)
+			return m, nil
+		}
+
+		msg.target.Children = msg.fresh.Children
+		msg.target.Size = msg.fresh.Size
+		msg.target.Error = msg.fresh.Error
+		msg.target.Errors = msg.fresh.Errors
+		msg.target.EntriesHaveErrors = msg.fresh.EntriesHaveErrors
+		msg.target.TotalFiles = msg.fresh.TotalFiles
+		msg.target.UnknownSizeFiles = msg.fresh.UnknownSizeFiles
+		msg.target.Skipped = msg.fresh.Skipped
+		for _, child := range msg.target.Children {
+			child.Parent = msg.target
+		}
+
+		if m.cursor >= len(msg.target.Children) {
+			m.cursor = 0
+		}
+
+		m.recalculateSizes(msg.target.Parent)
+		m.statusMsg = fmt.Sprintf("Rescanned: %s", filepath.Base(msg.target.Path))
+		m.statusExpiry = time.Now().Add(3 * time.Second)
+		return m, nil
+
 	case deleteProgressMsg:
 		// Animate spinner
 		if m.deleting {
@@ -317,6 +565,10 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		if msg.err != nil {
 			m.statusMsg = fmt.Sprintf("Error deleting: %v", msg.err)
 			m.statusExpiry = time.Now().Add(5 * time.Second)
+			if m.currentDir != nil {
+				m.currentDir.Errors = append(m.currentDir.Errors, FileError{Path: msg.target.Path, Err: msg.err})
+				m.currentDir.EntriesHaveErrors = true
+			}
 		} else {
 			m.statusMsg = fmt.Sprintf("Deleted: %s", msg.filename)
 			m.statusExpiry = time.Now().Add(3 * time.Second)
@@ -342,29 +594,72 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		return m, nil
 
 	case tea.KeyMsg:
-		if m.scanning || m.deleting {
+		if m.scanning || m.deleting || m.rescanning {
 			if msg.String() == "ctrl+c" || msg.String() == "q" {
 				return m, tea.Quit
 			}
 			return m, nil
 		}
 
+		if m.showErrors {
+			switch msg.String() {
+			case "ctrl+c", "q", "e", "esc":
+				m.showErrors = false
+			case "up", "k":
+				if m.errorScroll > 0 {
+					m.errorScroll--
+				}
+			case "down", "j":
+				if m.errorScroll < len(m.errorList)-1 {
+					m.errorScroll++
+				}
+			}
+			return m, nil
+		}
+
 		switch msg.String() {
 		case "ctrl+c", "q":
 			return m, tea.Quit
 
+		case "e":
+			if m.currentDir != nil {
+				m.errorList = collectErrors(m.currentDir)
+				m.errorScroll = 0
+				m.showErrors = true
+			}
+
+		case "x":
+			if m.rootDir != nil {
+				path := m.exportPath
+				if path == "" {
+					path = defaultExportPath(m.exportFormat)
+				}
+				if err := exportTree(m.rootDir, path, m.exportFormat); err != nil {
+					m.statusMsg = fmt.Sprintf("Export failed: %v", err)
+				} else {
+					m.statusMsg = fmt.Sprintf("Exported to %s", path)
+				}
+				m.statusExpiry = time.Now().Add(3 * time.Second)
+			}
+
 		case "up", "k":
 			if m.cursor > 0 {
 				m.cursor--
 			}
 
 		case "down", "j":
-			if m.currentDir != nil && m.cursor < len(m.currentDir.Children)-1 {
-				m.cursor++
+			if m.currentDir != nil {
+				limit := len(m.currentDir.Children)
+				if m.treeView {
+					limit = len(m.visibleRows())
+				}
+				if m.cursor < limit-1 {
+					m.cursor++
+				}
 			}
 
 		case "enter", "right", "l":
-			if m.currentDir != nil && len(m.currentDir.Children) > 0 {
+			if !m.treeView && m.currentDir != nil && len(m.currentDir.Children) > 0 {
 				selected := m.currentDir.Children[m.cursor]
 				if selected.IsDir && len(selected.Children) > 0 {
 					m.currentDir = selected
@@ -373,7 +668,7 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			}
 
 		case "left", "h", "backspace":
-			if m.currentDir != nil && m.currentDir.Parent != nil {
+			if !m.treeView && m.currentDir != nil && m.currentDir.Parent != nil {
 				// Find cursor position in parent
 				parent := m.currentDir.Parent
 				for i, child := range parent.Children {
@@ -385,6 +680,28 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				m.currentDir = parent
 			}
 
+		case "t":
+			// Toggle the recursive tree view
+			m.treeView = !m.treeView
+			if m.treeView {
+				if m.expanded == nil {
+					m.expanded = map[*DirInfo]bool{}
+				}
+				m.expanded[m.rootDir] = true
+			}
+			m.cursor = 0
+
+		case "+", "-", " ":
+			if m.treeView {
+				rows := m.visibleRows()
+				if m.cursor < len(rows) {
+					dir := rows[m.cursor].dir
+					if dir.IsDir && len(dir.Children) > 0 {
+						m.expanded[dir] = !m.expanded[dir]
+					}
+				}
+			}
+
 		case "s":
 			// Toggle sort
 			if m.sortBy == "size" {
@@ -400,9 +717,20 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			m.currentDir = m.rootDir
 			m.cursor = 0
 
+		case "r":
+			// Rescan the current directory in place
+			if m.currentDir != nil {
+				target := m.currentDir
+				m.rescanning = true
+				m.rescanTarget = target.Path
+				m.scanProgressTop = nil
+				m.startTime = time.Now()
+				return m, tea.Batch(m.rescanCmd(target), scanTick)
+			}
+
 		case "d":
 			// Delete file/folder asynchronously
-			if m.currentDir != nil && len(m.currentDir.Children) > 0 {
+			if !m.treeView && m.currentDir != nil && len(m.currentDir.Children) > 0 {
 				m.deleteTarget = m.currentDir.Children[m.cursor]
 				m.deleteFilename = filepath.Base(m.deleteTarget.Path)
 				m.deleting = true
@@ -415,6 +743,69 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	return m, nil
 }
 
+// snapshotTopChildren returns a race-free copy of dir's children slice for
+// display while the concurrent scanner may still be appending to it.
+func snapshotTopChildren(dir *DirInfo) []*DirInfo {
+	if dir == nil {
+		return nil
+	}
+	dir.mu.Lock()
+	defer dir.mu.Unlock()
+
+	children := make([]*DirInfo, len(dir.Children))
+	copy(children, dir.Children)
+	return children
+}
+
+// collectErrors gathers every FileError recorded at or beneath dir.
+func collectErrors(dir *DirInfo) []FileError {
+	if dir == nil {
+		return nil
+	}
+
+	errs := append([]FileError{}, dir.Errors...)
+	for _, child := range dir.Children {
+		errs = append(errs, collectErrors(child)...)
+	}
+	return errs
+}
+
+// row is one flattened line of the tree view.
+type row struct {
+	dir    *DirInfo
+	prefix string // box-drawing prefix rendered before the name, e.g. "├── "
+}
+
+// visibleRows flattens the tree rooted at m.rootDir into the set of rows
+// that should currently be drawn, respecting m.expanded. Cursor movement and
+// rendering in tree mode both operate on this flattened slice.
+func (m model) visibleRows() []row {
+	if m.rootDir == nil {
+		return nil
+	}
+
+	rows := []row{{dir: m.rootDir}}
+
+	var walk func(dir *DirInfo, ancestorPrefix string)
+	walk = func(dir *DirInfo, ancestorPrefix string) {
+		if !m.expanded[dir] {
+			return
+		}
+		for i, child := range dir.Children {
+			last := i == len(dir.Children)-1
+			connector, nextAncestor := "├── ", ancestorPrefix+"│   "
+			if last {
+				connector, nextAncestor = "└── ", ancestorPrefix+"    "
+			}
+			rows = append(rows, row{dir: child, prefix: ancestorPrefix + connector})
+			walk(child, nextAncestor)
+		}
+	}
+	walk(m.rootDir, "")
+
+	return rows
+}
+
 func (m *model) sortChildren(dir *DirInfo) {
 	if dir == nil {
 		return
@@ -423,7 +814,7 @@ func (m *model) sortChildren(dir *DirInfo) {
 	if m.sortBy == "name" {
 		sort.Slice(dir.Children, func(i, j int) bool {
 			return strings.ToLower(filepath.Base(dir.Children[i].Path)) <
-				   strings.ToLower(filepath.Base(dir.Children[j].Path))
+				strings.ToLower(filepath.Base(dir.Children[j].Path))
 		})
 	} else {
 		sort.Slice(dir.Children, func(i, j int) bool {
@@ -437,26 +828,30 @@ func (m *model) sortChildren(dir *DirInfo) {
 }
 
 func (m model) View() string {
-	if m.scanning {
+	if m.scanning || m.rescanning {
 		elapsed := time.Since(m.startTime).Seconds()
 		spinner := spinnerFrames[int(elapsed*10)%len(spinnerFrames)]
 
 		var b strings.Builder
-		b.WriteString(fmt.Sprintf("\n  %s Scanning directories... %.1fs\n\n", spinner, elapsed))
+		if m.rescanning {
+			b.WriteString(fmt.Sprintf("\n  %s Rescanning %s... %.1fs\n\n", spinner, m.rescanTarget, elapsed))
+		} else {
+			b.WriteString(fmt.Sprintf("\n  %s Scanning directories... %.1fs\n\n", spinner, elapsed))
+		}
 
-		if m.scanProgress != nil {
+		if m.scanProgressTop != nil {
 			b.WriteString(fmt.Sprintf("  Files: %d | Directories: %d\n", m.filesScanned, m.dirsScanned))
 			b.WriteString(fmt.Sprintf("  Current size: %s\n\n", formatSize(m.totalSize)))
 
 			// Show top 5 largest items found so far
-			if len(m.scanProgress.Children) > 0 {
+			if len(m.scanProgressTop) > 0 {
 				b.WriteString("  Largest items found:\n")
 				limit := 5
-				if len(m.scanProgress.Children) < limit {
-					limit = len(m.scanProgress.Children)
+				if len(m.scanProgressTop) < limit {
+					limit = len(m.scanProgressTop)
 				}
 				for i := 0; i < limit; i++ {
-					child := m.scanProgress.Children[i]
+					child := m.scanProgressTop[i]
 					name := filepath.Base(child.Path)
 					if child.IsDir {
 						name += "/"
@@ -482,14 +877,34 @@ func (m model) View() string {
 		return "\n  No data available\n"
 	}
 
+	if m.showErrors {
+		return m.renderErrorsModal()
+	}
+
 	var b strings.Builder
 
+	// In tree view the rows are drawn from the whole tree (m.rootDir), so the
+	// header must describe that tree rather than whatever subdirectory was
+	// current when 't' was pressed, matching the totalForPercent branch below.
+	headerDir := m.currentDir
+	if m.treeView {
+		headerDir = m.rootDir
+	}
+
 	// Title
-	title := fmt.Sprintf(" Dusty - %s ", m.currentDir.Path)
+	title := fmt.Sprintf(" Dusty - %s ", headerDir.Path)
 	b.WriteString(titleStyle.Render(title))
 	b.WriteString("\n")
-	b.WriteString(fmt.Sprintf("Total Size: %s | Sort: %s\n\n",
-		formatSize(m.currentDir.Size), m.sortBy))
+	b.WriteString(fmt.Sprintf("Total Size: %s | Sort: %s\n",
+		formatSize(headerDir.Size), m.sortBy))
+	if headerDir.UnknownSizeFiles > 0 {
+		b.WriteString(warnStyle.Render(fmt.Sprintf("  [%d of %d files have unknown size, size may be underestimated]\n",
+			headerDir.UnknownSizeFiles, headerDir.TotalFiles)))
+	}
+	if len(headerDir.Skipped) > 0 {
+		b.WriteString(helpStyle.Render(fmt.Sprintf("  [skipped: %s]\n", strings.Join(headerDir.Skipped, ", "))))
+	}
+	b.WriteString("\n")
 
 	// Directory listing
 	maxItems := m.height - 8
@@ -497,47 +912,33 @@ func (m model) View() string {
 		maxItems = 5
 	}
 
+	var rows []row
+	totalForPercent := m.currentDir.Size
+	if m.treeView {
+		rows = m.visibleRows()
+		totalForPercent = m.rootDir.Size
+	} else {
+		for _, child := range m.currentDir.Children {
+			rows = append(rows, row{dir: child})
+		}
+	}
+
 	startIdx := 0
 	if m.cursor >= maxItems {
 		startIdx = m.cursor - maxItems + 1
 	}
 
-	for i := startIdx; i < len(m.currentDir.Children) && i < startIdx+maxItems; i++ {
-		child := m.currentDir.Children[i]
-		name := filepath.Base(child.Path)
-
-		// Calculate percentage
-		percentage := float64(0)
-		if m.currentDir.Size > 0 {
-			percentage = float64(child.Size) / float64(m.currentDir.Size) * 100
-		}
-
-		// Format line
-		sizeStr := formatSize(child.Size)
-		percentStr := fmt.Sprintf("%5.1f%%", percentage)
-
-		// Create bar
-		barWidth := 20
-		filledWidth := int(percentage / 100.0 * float64(barWidth))
-		if filledWidth > barWidth {
-			filledWidth = barWidth
-		}
-		bar := strings.Repeat("█", filledWidth) + strings.Repeat("░", barWidth-filledWidth)
-
-		// Style the name
-		var nameStr string
-		if child.IsDir {
-			nameStr = dirStyle.Render(name + "/")
-		} else {
-			nameStr = fileStyle.Render(name)
+	for i := startIdx; i < len(rows) && i < startIdx+maxItems; i++ {
+		toggle := ""
+		if m.treeView && rows[i].dir.IsDir && len(rows[i].dir.Children) > 0 {
+			if m.expanded[rows[i].dir] {
+				toggle = "[-] "
+			} else {
+				toggle = "[+] "
+			}
 		}
 
-		line := fmt.Sprintf("  %s %s [%s] %s",
-			sizeStyle.Render(fmt.Sprintf("%10s", sizeStr)),
-			percentStr,
-			bar,
-			nameStr)
-
+		line := formatEntryLine(rows[i].dir, totalForPercent, rows[i].prefix+toggle)
 		if i == m.cursor {
 			line = selectedStyle.Render(line)
 		}
@@ -556,13 +957,97 @@ func (m model) View() string {
 		b.WriteString(m.statusMsg)
 	} else {
 		// Help text
-		b.WriteString(helpStyle.Render("  ↑/↓: Navigate | ←/→: Enter/Exit | d: Delete | s: Sort | Home: Root | q: Quit"))
+		b.WriteString(helpStyle.Render("  ↑/↓: Navigate | ←/→: Enter/Exit | d: Delete | r: Rescan | s: Sort | e: Errors | t: Tree | Home: Root | q: Quit"))
 	}
 	b.WriteString("\n")
 
 	return b.String()
 }
 
+// renderErrorsModal renders a scrollable list of the scan errors recorded
+// under the current directory, opened with the 'e' keybinding.
+func (m model) renderErrorsModal() string {
+	var b strings.Builder
+
+	title := fmt.Sprintf(" Errors under %s ", m.currentDir.Path)
+	b.WriteString(titleStyle.Render(title))
+	b.WriteString("\n\n")
+
+	if len(m.errorList) == 0 {
+		b.WriteString("  No errors recorded.\n")
+	} else {
+		maxItems := m.height - 8
+		if maxItems < 5 {
+			maxItems = 5
+		}
+
+		startIdx := 0
+		if m.errorScroll >= maxItems {
+			startIdx = m.errorScroll - maxItems + 1
+		}
+
+		for i := startIdx; i < len(m.errorList) && i < startIdx+maxItems; i++ {
+			fe := m.errorList[i]
+			line := fmt.Sprintf("  %s: %v", fe.Path, fe.Err)
+			if i == m.errorScroll {
+				line = selectedStyle.Render(line)
+			} else {
+				line = warnStyle.Render(line)
+			}
+			b.WriteString(line)
+			b.WriteString("\n")
+		}
+	}
+
+	b.WriteString("\n")
+	b.WriteString(helpStyle.Render("  ↑/↓: Scroll | e/Esc/q: Close"))
+	b.WriteString("\n")
+
+	return b.String()
+}
+
+// formatEntryLine renders the size/percent/bar/name columns shared by both
+// the flat listing and the tree view. prefix is rendered between the bar and
+// the name, holding the tree view's box-drawing connector (and expand/
+// collapse toggle) or left empty in the flat view.
+func formatEntryLine(dir *DirInfo, totalSize int64, prefix string) string {
+	percentage := float64(0)
+	if totalSize > 0 {
+		percentage = float64(dir.Size) / float64(totalSize) * 100
+	}
+
+	sizeStr := formatSize(dir.Size)
+	percentStr := fmt.Sprintf("%5.1f%%", percentage)
+
+	barWidth := 20
+	filledWidth := int(percentage / 100.0 * float64(barWidth))
+	if filledWidth > barWidth {
+		filledWidth = barWidth
+	}
+	bar := strings.Repeat("█", filledWidth) + strings.Repeat("░", barWidth-filledWidth)
+
+	name := filepath.Base(dir.Path)
+	var nameStr string
+	if dir.IsDir {
+		nameStr = dirStyle.Render(name + "/")
+	} else {
+		nameStr = fileStyle.Render(name)
+	}
+
+	warnPrefix := "  "
+	if dir.Error != nil || dir.EntriesHaveErrors {
+		warnPrefix = warnStyle.Render("! ")
+	}
+
+	return fmt.Sprintf("%s%s %s [%s] %s%s",
+		warnPrefix,
+		sizeStyle.Render(fmt.Sprintf("%10s", sizeStr)),
+		percentStr,
+		bar,
+		prefix,
+		nameStr)
+}
+
 // formatSize formats bytes into human-readable format
 func formatSize(bytes int64) string {
 	const unit = 1024
@@ -579,18 +1064,30 @@ func formatSize(bytes int64) string {
 	return fmt.Sprintf("%.1f %cB", float64(bytes)/float64(div), "KMGTPE"[exp])
 }
 
-// recalculateSizes recalculates sizes up the directory tree
+// recalculateSizes recalculates dir's aggregate fields (Size, EntriesHaveErrors,
+// TotalFiles, UnknownSizeFiles) from its children and propagates upward, the
+// same rollup calculateDirSizeWithProgress does for a freshly scanned subtree.
+// Without this, a rescan or delete that changes a descendant's error/unknown-
+// size state would leave every ancestor's fields permanently stale.
 func (m *model) recalculateSizes(dir *DirInfo) {
 	if dir == nil {
 		return
 	}
 
-	// Recalculate current directory size
-	var totalSize int64
+	var totalSize, totalFiles, unknownSizeFiles int64
+	hasErrors := len(dir.Errors) > 0
 	for _, child := range dir.Children {
 		totalSize += child.Size
+		totalFiles += child.TotalFiles
+		unknownSizeFiles += child.UnknownSizeFiles
+		if child.Error != nil || child.EntriesHaveErrors {
+			hasErrors = true
+		}
 	}
 	dir.Size = totalSize
+	dir.TotalFiles = totalFiles
+	dir.UnknownSizeFiles = unknownSizeFiles
+	dir.EntriesHaveErrors = hasErrors
 
 	// Recursively update parent sizes
 	if dir.Parent != nil {
@@ -617,19 +1114,75 @@ func deleteProgress() tea.Cmd {
 	})
 }
 
+// stringListFlag collects a repeatable string flag, like --ignore-dir, into
+// a slice.
+type stringListFlag []string
+
+func (s *stringListFlag) String() string { return strings.Join(*s, ",") }
+
+func (s *stringListFlag) Set(v string) error {
+	*s = append(*s, v)
+	return nil
+}
+
 func main() {
+	jobs := flag.Int("jobs", runtime.NumCPU(), "number of directories to scan concurrently")
+
+	var oneFileSystem bool
+	flag.BoolVar(&oneFileSystem, "one-file-system", false, "don't descend into directories on other filesystems")
+	flag.BoolVar(&oneFileSystem, "x", false, "shorthand for --one-file-system")
+
+	var ignoreDirs stringListFlag
+	flag.Var(&ignoreDirs, "ignore-dir", "glob pattern of directory names to skip (repeatable)")
+
+	exportPath := flag.String("export", "", "write the scan results to this file once scanning finishes")
+	importPath := flag.String("import", "", "load a previously exported file and skip scanning")
+	format := flag.String("format", "json", "export format: json or csv")
+
+	flag.Parse()
+
+	if *importPath != "" {
+		root, err := importTree(*importPath)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+
+		p := tea.NewProgram(importedModel(root, *jobs, *exportPath, *format), tea.WithAltScreen())
+		if _, err := p.Run(); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+
 	path := "."
-	if len(os.Args) > 1 {
-		path = os.Args[1]
+	if flag.NArg() > 0 {
+		path = flag.Arg(0)
 	}
 
 	// Verify path exists
-	if _, err := os.Stat(path); err != nil {
+	rootInfo, err := os.Stat(path)
+	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 		os.Exit(1)
 	}
 
-	p := tea.NewProgram(initialModel(path), tea.WithAltScreen())
+	cfg := scanConfig{ignoreDirs: ignoreDirs}
+	if oneFileSystem {
+		if dev, ok := deviceID(rootInfo); ok {
+			cfg.oneFileSystem = true
+			cfg.rootDevice = dev
+		} else {
+			fmt.Fprintln(os.Stderr, "Warning: --one-file-system is not supported on this platform; ignoring")
+		}
+	}
+
+	m := initialModel(path, *jobs, cfg)
+	m.exportPath = *exportPath
+	m.exportFormat = *format
+
+	p := tea.NewProgram(m, tea.WithAltScreen())
 	if _, err := p.Run(); err != nil {
 		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 		os.Exit(1)
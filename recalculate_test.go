@@ -0,0 +1,44 @@
+package main
+
+import "testing"
+
+// TestRecalculateSizesRollsUpAggregates guards against the staleness a
+// reviewer found: after a rescan or delete, recalculateSizes must refresh
+// EntriesHaveErrors/TotalFiles/UnknownSizeFiles up the tree, not just Size.
+func TestRecalculateSizesRollsUpAggregates(t *testing.T) {
+	root := &DirInfo{Path: "/root", IsDir: true}
+	child := &DirInfo{Path: "/root/child", Parent: root, IsDir: true}
+	root.Children = []*DirInfo{child}
+
+	grandchild := &DirInfo{
+		Path:              "/root/child/bad",
+		Parent:            child,
+		Error:             errNoPermission,
+		EntriesHaveErrors: true,
+		TotalFiles:        1,
+		UnknownSizeFiles:  1,
+	}
+	child.Children = []*DirInfo{grandchild}
+
+	m := &model{}
+	m.recalculateSizes(child)
+
+	if !root.EntriesHaveErrors || root.TotalFiles != 1 || root.UnknownSizeFiles != 1 {
+		t.Fatalf("root did not pick up child's error state: %+v", root)
+	}
+
+	// Simulate a rescan that found the error gone.
+	child.Children = nil
+	child.Errors = nil
+	m.recalculateSizes(child)
+
+	if root.EntriesHaveErrors || root.TotalFiles != 0 || root.UnknownSizeFiles != 0 {
+		t.Fatalf("root stayed stale after the error cleared: %+v", root)
+	}
+}
+
+var errNoPermission = &testError{"permission denied"}
+
+type testError struct{ msg string }
+
+func (e *testError) Error() string { return e.msg }
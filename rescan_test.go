@@ -0,0 +1,29 @@
+package main
+
+import "testing"
+
+// TestRescanCompleteRefreshesSkipped guards against the staleness a reviewer
+// found: every other per-directory field gained by later requests (Errors,
+// EntriesHaveErrors, TotalFiles, UnknownSizeFiles) was copied from the fresh
+// rescan onto the target, but Skipped was not, so the "[skipped: ...]"
+// badge never reflected a rescan that picked up a new ignore-dir match.
+func TestRescanCompleteRefreshesSkipped(t *testing.T) {
+	root := &DirInfo{Path: "/root", IsDir: true}
+	target := &DirInfo{Path: "/root/proj", Parent: root, IsDir: true, Skipped: []string{"node_modules"}}
+	root.Children = []*DirInfo{target}
+
+	fresh := &DirInfo{
+		Path:    "/root/proj",
+		IsDir:   true,
+		Skipped: []string{"node_modules", "vendor"},
+	}
+
+	m := model{rootDir: root, currentDir: target}
+	updated, _ := m.Update(rescanCompleteMsg{target: target, fresh: fresh})
+	m = updated.(model)
+
+	want := []string{"node_modules", "vendor"}
+	if got := target.Skipped; !equalStrings(got, want) {
+		t.Fatalf("target.Skipped = %v, want %v", got, want)
+	}
+}
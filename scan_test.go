@@ -0,0 +1,45 @@
+package main
+
+import (
+	"os"
+	"sync"
+	"testing"
+)
+
+// TestScanDirectoryConcurrentProgressRead exercises the same pattern
+// `go test -race` catches in practice: one goroutine running a scan while
+// another polls the package-level currentRoot/currentCounters the way
+// scanTickMsg does, concurrently with calculateDirSizeWithProgress appending
+// to DirInfo.Children. Both sides must only touch currentRoot/currentCounters
+// through their atomic accessors.
+func TestScanDirectoryConcurrentProgressRead(t *testing.T) {
+	dir := t.TempDir()
+	for i := 0; i < 20; i++ {
+		sub := dir + "/d" + string(rune('a'+i))
+		if err := os.MkdirAll(sub, 0755); err != nil {
+			t.Fatalf("setup: %v", err)
+		}
+	}
+
+	var wg sync.WaitGroup
+	wg.Add(2)
+
+	go func() {
+		defer wg.Done()
+		scanDirectory(dir, 4, scanConfig{})
+	}()
+
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 1000; i++ {
+			_ = snapshotTopChildren(currentRoot.Load())
+			if counters := currentCounters.Load(); counters != nil {
+				_ = counters.filesScanned.Load()
+				_ = counters.dirsScanned.Load()
+				_ = counters.totalSize.Load()
+			}
+		}
+	}()
+
+	wg.Wait()
+}
@@ -0,0 +1,130 @@
+package main
+
+import (
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// buildExpandTestTree returns root -> [a (with child a1), b].
+func buildExpandTestTree() *DirInfo {
+	root := &DirInfo{Path: "/root", IsDir: true}
+	a := &DirInfo{Path: "/root/a", Parent: root, IsDir: true}
+	a1 := &DirInfo{Path: "/root/a/a1", Parent: a, IsDir: true}
+	b := &DirInfo{Path: "/root/b", Parent: root, IsDir: true}
+	a.Children = []*DirInfo{a1}
+	root.Children = []*DirInfo{a, b}
+	return root
+}
+
+func rowPaths(rows []row) []string {
+	paths := make([]string, len(rows))
+	for i, r := range rows {
+		paths[i] = r.dir.Path
+	}
+	return paths
+}
+
+func TestVisibleRowsRespectsExpansion(t *testing.T) {
+	root := buildExpandTestTree()
+	a := root.Children[0]
+
+	m := model{rootDir: root, expanded: map[*DirInfo]bool{}}
+
+	// Nothing expanded: only the root row shows.
+	if got := rowPaths(m.visibleRows()); len(got) != 1 || got[0] != root.Path {
+		t.Fatalf("collapsed rows = %v, want [%s]", got, root.Path)
+	}
+
+	// Expanding the root reveals its direct children, not grandchildren.
+	m.expanded[root] = true
+	want := []string{"/root", "/root/a", "/root/b"}
+	if got := rowPaths(m.visibleRows()); !equalStrings(got, want) {
+		t.Fatalf("rows after expanding root = %v, want %v", got, want)
+	}
+
+	// Expanding "a" as well reveals a1 spliced in directly after it.
+	m.expanded[a] = true
+	want = []string{"/root", "/root/a", "/root/a/a1", "/root/b"}
+	if got := rowPaths(m.visibleRows()); !equalStrings(got, want) {
+		t.Fatalf("rows after expanding a = %v, want %v", got, want)
+	}
+
+	// Collapsing "a" again hides a1 without disturbing its sibling "b".
+	m.expanded[a] = false
+	want = []string{"/root", "/root/a", "/root/b"}
+	if got := rowPaths(m.visibleRows()); !equalStrings(got, want) {
+		t.Fatalf("rows after re-collapsing a = %v, want %v", got, want)
+	}
+}
+
+// TestTreeViewCursorStaysInBoundsAcrossCollapse drives the 't'/'down'/'+'
+// keybindings the way a user would: cursor movement must stay clamped to
+// whatever visibleRows() currently returns, including right after a
+// collapse shrinks the flattened row list out from under the cursor.
+func TestTreeViewCursorStaysInBoundsAcrossCollapse(t *testing.T) {
+	root := buildExpandTestTree()
+	a := root.Children[0]
+	m := model{rootDir: root, currentDir: root, expanded: map[*DirInfo]bool{}}
+
+	m, _ = sendKey(m, "t") // enter tree view (root auto-expanded): rows = [root, a, b]
+
+	m, _ = sendKey(m, "down") // cursor -> row 1, "a"
+	if got := m.visibleRows()[m.cursor].dir; got != a {
+		t.Fatalf("cursor row = %s, want %s", got.Path, a.Path)
+	}
+
+	m, _ = sendKey(m, "+") // expand "a": rows = [root, a, a1, b]
+	if !m.expanded[a] {
+		t.Fatalf("expected 'a' to be expanded after '+'")
+	}
+
+	for i := 0; i < 10; i++ {
+		m, _ = sendKey(m, "down")
+	}
+	rows := m.visibleRows()
+	if m.cursor != len(rows)-1 {
+		t.Fatalf("cursor = %d after running off the end, want %d (len(rows)-1)", m.cursor, len(rows)-1)
+	}
+
+	// Cursor now sits on the last row ("b"). Move it back onto "a" and
+	// collapse: the row list shrinks by one (a1 disappears), and the
+	// cursor must still index a valid row on the next render.
+	m.cursor = 1 // "/root/a"
+	m, _ = sendKey(m, "+")
+	if m.expanded[a] {
+		t.Fatalf("expected 'a' to be collapsed after second '+'")
+	}
+	rows = m.visibleRows()
+	if m.cursor >= len(rows) {
+		t.Fatalf("cursor %d out of bounds for %d rows after collapsing", m.cursor, len(rows))
+	}
+}
+
+func sendKey(m model, key string) (model, tea.Cmd) {
+	updated, cmd := m.Update(keyMsgFor(key))
+	return updated.(model), cmd
+}
+
+func keyMsgFor(key string) tea.KeyMsg {
+	switch key {
+	case "down":
+		return tea.KeyMsg{Type: tea.KeyDown}
+	case "up":
+		return tea.KeyMsg{Type: tea.KeyUp}
+	default:
+		return tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(key)}
+	}
+}
+
+func equalStrings(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}